@@ -0,0 +1,109 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/haliivi/go-todo-app/pkg/repository"
+)
+
+// newTestPool boots an ephemeral Postgres container, applies the schema,
+// and returns a pool connected to it. Each call gets its own database, so
+// tests using it are safe to run with t.Parallel().
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:16-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "todo",
+				"POSTGRES_PASSWORD": "todo",
+				"POSTGRES_DB":       "todo",
+			},
+			WaitingFor: wait.ForListeningPort("5432/tcp").WithStartupTimeout(30 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("start postgres container: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Logf("terminate postgres container: %v", err)
+		}
+	})
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("container host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "5432")
+	if err != nil {
+		t.Fatalf("container port: %v", err)
+	}
+
+	pool, err := repository.NewPostgresDB(ctx, repository.Config{
+		Host:     host,
+		Port:     port.Port(),
+		Username: "todo",
+		Password: "todo",
+		DBName:   "todo",
+		SSLmode:  "disable",
+	})
+	if err != nil {
+		t.Fatalf("connect to postgres: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	schema, err := os.ReadFile("../../schema/schema.sql")
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	if _, err := pool.Exec(ctx, string(schema)); err != nil {
+		t.Fatalf("apply schema: %v", err)
+	}
+
+	return pool
+}
+
+// seedUserWithListAndItem inserts a user, a list owned by that user, and a
+// single item on that list, returning their ids.
+func seedUserWithListAndItem(ctx context.Context, t *testing.T, pool *pgxpool.Pool, username string) (userId, listId, itemId int) {
+	t.Helper()
+
+	err := pool.QueryRow(ctx,
+		"INSERT INTO users (name, username, password_hash) VALUES ($1, $1, 'x') RETURNING id", username,
+	).Scan(&userId)
+	if err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	err = pool.QueryRow(ctx, "INSERT INTO todo_lists (title, description) VALUES ('list', '') RETURNING id").Scan(&listId)
+	if err != nil {
+		t.Fatalf("seed list: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO users_lists (user_id, list_id) VALUES ($1, $2)", userId, listId); err != nil {
+		t.Fatalf("seed users_lists: %v", err)
+	}
+
+	err = pool.QueryRow(ctx, "INSERT INTO todo_items (title, description) VALUES ('item', '') RETURNING id").Scan(&itemId)
+	if err != nil {
+		t.Fatalf("seed item: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO lists_items (list_id, item_id) VALUES ($1, $2)", listId, itemId); err != nil {
+		t.Fatalf("seed lists_items: %v", err)
+	}
+
+	return userId, listId, itemId
+}
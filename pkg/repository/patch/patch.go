@@ -0,0 +1,59 @@
+// Package patch builds parameterized PATCH-style UPDATE queries from a map
+// of non-nil fields, replacing the setValues/args/argId accumulation that
+// used to be copy-pasted into every repository's Update method.
+package patch
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/haliivi/go-todo-app"
+)
+
+// BuildUpdate renders an UPDATE table SET ... query from the non-nil
+// entries of fields, restricted to the allowed column names, further
+// constrained by where. Columns are emitted in allowed's order, so the
+// same patch always produces the same SQL and argument list, which makes
+// the result deterministic enough to assert on in tests.
+//
+// An empty patch returns todo.ErrNoUpdateFields instead of the invalid
+// "SET  FROM ..." SQL the hand-rolled version used to produce, and a
+// field outside allowed is rejected rather than silently interpolated.
+func BuildUpdate(table string, fields map[string]interface{}, allowed []string, where ...sq.Sqlizer) (string, []interface{}, error) {
+	for column := range fields {
+		if !contains(allowed, column) {
+			return "", nil, fmt.Errorf("patch: unknown column %q for table %q", column, table)
+		}
+	}
+
+	builder := sq.Update(table).PlaceholderFormat(sq.Dollar)
+
+	set := false
+	for _, column := range allowed {
+		value, ok := fields[column]
+		if !ok {
+			continue
+		}
+		builder = builder.Set(column, value)
+		set = true
+	}
+	if !set {
+		return "", nil, todo.ErrNoUpdateFields
+	}
+
+	for _, predicate := range where {
+		builder = builder.Where(predicate)
+	}
+
+	return builder.ToSql()
+}
+
+func contains(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
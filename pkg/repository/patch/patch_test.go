@@ -0,0 +1,82 @@
+package patch
+
+import (
+	"errors"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/haliivi/go-todo-app"
+)
+
+func TestBuildUpdate_EmptyPatchReturnsErrNoUpdateFields(t *testing.T) {
+	_, _, err := BuildUpdate("todo_items", map[string]interface{}{}, []string{"title", "description", "done"}, sq.Eq{"id": 1})
+	if !errors.Is(err, todo.ErrNoUpdateFields) {
+		t.Fatalf("expected todo.ErrNoUpdateFields, got %v", err)
+	}
+}
+
+func TestBuildUpdate_RejectsUnknownColumns(t *testing.T) {
+	fields := map[string]interface{}{"title": "new title", "is_admin": true}
+	allowed := []string{"title", "description", "done"}
+
+	_, _, err := BuildUpdate("todo_items", fields, allowed, sq.Eq{"id": 1})
+	if err == nil {
+		t.Fatalf("expected an error for the unknown column %q, got nil", "is_admin")
+	}
+	if errors.Is(err, todo.ErrNoUpdateFields) {
+		t.Fatalf("unknown column should not be reported as an empty patch")
+	}
+}
+
+func TestBuildUpdate_StableColumnOrderAndPlaceholders(t *testing.T) {
+	allowed := []string{"title", "description", "done"}
+
+	// The map is built with keys in reverse of allowed's order; the
+	// resulting SQL must still follow allowed's order every time, with
+	// placeholders numbered in that same order.
+	fields := map[string]interface{}{
+		"done":        true,
+		"description": "2%",
+		"title":       "buy milk",
+	}
+
+	const wantQuery = "UPDATE todo_items SET title = $1, description = $2, done = $3 WHERE id = $4"
+	wantArgs := []interface{}{"buy milk", "2%", true, 1}
+
+	for i := 0; i < 10; i++ {
+		query, args, err := BuildUpdate("todo_items", fields, allowed, sq.Eq{"id": 1})
+		if err != nil {
+			t.Fatalf("BuildUpdate: %v", err)
+		}
+		if query != wantQuery {
+			t.Fatalf("run %d: query = %q, want %q", i, query, wantQuery)
+		}
+		if len(args) != len(wantArgs) {
+			t.Fatalf("run %d: args = %v, want %v", i, args, wantArgs)
+		}
+		for j, arg := range args {
+			if arg != wantArgs[j] {
+				t.Fatalf("run %d: args[%d] = %v, want %v", i, j, arg, wantArgs[j])
+			}
+		}
+	}
+}
+
+func TestBuildUpdate_OmitsColumnsNotInPatch(t *testing.T) {
+	allowed := []string{"title", "description", "done"}
+	fields := map[string]interface{}{"done": false}
+
+	query, args, err := BuildUpdate("todo_items", fields, allowed, sq.Eq{"id": 1})
+	if err != nil {
+		t.Fatalf("BuildUpdate: %v", err)
+	}
+
+	const wantQuery = "UPDATE todo_items SET done = $1 WHERE id = $2"
+	if query != wantQuery {
+		t.Fatalf("query = %q, want %q", query, wantQuery)
+	}
+	if len(args) != 2 || args[0] != false || args[1] != 1 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
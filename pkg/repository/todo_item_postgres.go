@@ -1,95 +1,196 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strings"
+
+	sq "github.com/Masterminds/squirrel"
 
 	"github.com/haliivi/go-todo-app"
-	"github.com/jmoiron/sqlx"
+	"github.com/haliivi/go-todo-app/pkg/repository/patch"
+	"github.com/haliivi/go-todo-app/pkg/repository/pgcore"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
 
+// MaxCreateBatchItems bounds how many items a single CreateBatch call will
+// accept. The HTTP-facing handler is expected to enforce this (or a
+// stricter, configurable) limit before the request ever reaches the
+// repository; this is a defense-in-depth backstop, not the configurable
+// limit called for at the API layer.
+const MaxCreateBatchItems = 5000
+
+// ErrCreateBatchTooLarge is returned by CreateBatch when the caller
+// supplies more than MaxCreateBatchItems items.
+var ErrCreateBatchTooLarge = errors.New("repository: batch exceeds MaxCreateBatchItems")
+
 type TodoItemPostgres struct {
-	db *sqlx.DB
+	pool txBeginner
 }
 
-func NewTodoItemPostgres(db *sqlx.DB) *TodoItemPostgres {
-	return &TodoItemPostgres{db: db}
+func NewTodoItemPostgres(pool *pgxpool.Pool) *TodoItemPostgres {
+	return &TodoItemPostgres{pool: pool}
 }
 
-func (r *TodoItemPostgres) Create(listId int, input todo.TodoItem) (int, error) {
-	tx, err := r.db.Begin()
+func (r *TodoItemPostgres) Create(ctx context.Context, listId int, input todo.TodoItem) (int, error) {
+	var itemId int32
+
+	err := WithTx(ctx, r.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		q := pgcore.New(tx)
+
+		id, err := q.CreateTodoItem(ctx, input.Title, input.Description)
+		if err != nil {
+			logrus.WithError(err).Error("failed to insert todo item")
+			return err
+		}
+		itemId = id
+
+		if err := q.LinkItemToList(ctx, int32(listId), itemId); err != nil {
+			logrus.WithError(err).Error("failed to link todo item to list")
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		fmt.Println("3")
 		return 0, err
 	}
 
-	createItemQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id", todoItemTable)
-	var itemId int
-	row := tx.QueryRow(createItemQuery, input.Title, input.Description)
-	err = row.Scan(&itemId)
-	if err != nil {
-		fmt.Println("2")
-		tx.Rollback()
-		return 0, err
+	return int(itemId), nil
+}
+
+// CreateBatch bulk-inserts items into listId using COPY, reserving the
+// generated ids up front since COPY has no RETURNING clause. Everything
+// runs inside a single transaction, so a mid-batch failure leaves neither
+// table touched.
+//
+// This is the repository-only half of the bulk-import feature: the
+// POST /api/lists/:id/items/bulk handler and service-layer wiring that
+// exposes it over HTTP still need to be built against this method in a
+// follow-up change.
+func (r *TodoItemPostgres) CreateBatch(ctx context.Context, listId int, items []todo.TodoItem) ([]int, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	if len(items) > MaxCreateBatchItems {
+		return nil, ErrCreateBatchTooLarge
 	}
-	createListItemQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) VALUES ($1, $2)", listsItemsTable)
-	_, err = tx.Exec(createListItemQuery, listId, itemId)
+
+	var ids []int
+
+	err := WithTx(ctx, r.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		reserveIdsQuery := fmt.Sprintf("SELECT nextval(pg_get_serial_sequence('%s', 'id')) FROM generate_series(1, $1)", todoItemTable)
+		rows, err := tx.Query(ctx, reserveIdsQuery, len(items))
+		if err != nil {
+			return err
+		}
+		ids, err = pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return err
+		}
+
+		itemRows := make([][]interface{}, len(items))
+		for i, item := range items {
+			itemRows[i] = []interface{}{ids[i], item.Title, item.Description}
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{todoItemTable}, []string{"id", "title", "description"}, pgx.CopyFromRows(itemRows)); err != nil {
+			return err
+		}
+
+		listItemRows := make([][]interface{}, len(items))
+		for i, id := range ids {
+			listItemRows[i] = []interface{}{listId, id}
+		}
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{listsItemsTable}, []string{"list_id", "item_id"}, pgx.CopyFromRows(listItemRows))
+		return err
+	})
 	if err != nil {
-		fmt.Println("1")
-		tx.Rollback()
-		return 0, err
+		return nil, err
 	}
-	return itemId, tx.Commit()
+
+	return ids, nil
 }
 
-func (r *TodoItemPostgres) GetAll(userId, listId int) ([]todo.TodoItem, error) {
-	var items []todo.TodoItem
-	query := fmt.Sprintf("SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti INNER JOIN %s li ON li.item_id = ti.id INNER JOIN %s ul ON ul.list_id = li.list_id WHERE  li.list_id = $1 AND ul.user_id = $2", todoItemTable, listsItemsTable, usersListTable)
-	if err := r.db.Select(&items, query, listId, userId); err != nil {
+func (r *TodoItemPostgres) GetAll(ctx context.Context, userId, listId int) ([]todo.TodoItem, error) {
+	var rows []pgcore.TodoItem
+
+	err := WithTx(ctx, r.pool, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		var err error
+		rows, err = pgcore.New(tx).ListTodoItems(ctx, int32(listId), int32(userId))
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
+
+	items := make([]todo.TodoItem, len(rows))
+	for i, row := range rows {
+		items[i] = todoItemFromRow(row)
+	}
 	return items, nil
 }
 
-func (r *TodoItemPostgres) GetById(userId, itemId int) (todo.TodoItem, error) {
-	var item todo.TodoItem
-	query := fmt.Sprintf("SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti INNER JOIN %s li ON li.item_id = ti.id INNER JOIN %s ul ON ul.list_id = li.list_id WHERE  ti.id = $1 AND ul.user_id = $2", todoItemTable, listsItemsTable, usersListTable)
-	err := r.db.Get(&item, query, itemId, itemId)
-	return item, err
+func (r *TodoItemPostgres) GetById(ctx context.Context, userId, itemId int) (todo.TodoItem, error) {
+	var row pgcore.TodoItem
+
+	err := WithTx(ctx, r.pool, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		var err error
+		row, err = pgcore.New(tx).GetTodoItemById(ctx, int32(itemId), int32(userId))
+		return err
+	})
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+
+	return todoItemFromRow(row), nil
 }
 
-func (r *TodoItemPostgres) Delete(userId, itemId int) error {
-	query := fmt.Sprintf("DELETE FROM %s ti USING %s li, %s ul WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.id = $2", todoItemTable, listsItemsTable, usersListTable)
-	_, err := r.db.Exec(query, userId, itemId)
-	return err
+func (r *TodoItemPostgres) Delete(ctx context.Context, userId, itemId int) error {
+	return WithTx(ctx, r.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		return pgcore.New(tx).DeleteTodoItem(ctx, int32(userId), int32(itemId))
+	})
 }
 
-func (r *TodoItemPostgres) Update(userId, itemId int, input todo.UpdateItemInput) error {
-	setValues := make([]string, 0)
-	args := make([]interface{}, 0)
-	argId := 1
+var todoItemUpdatableColumns = []string{"title", "description", "done"}
+
+func (r *TodoItemPostgres) Update(ctx context.Context, userId, itemId int, input todo.UpdateItemInput) error {
+	fields := make(map[string]interface{})
 	if input.Title != nil {
-		setValues = append(setValues, fmt.Sprintf("title=$%d", argId))
-		args = append(args, *input.Title)
-		argId++
+		fields["title"] = *input.Title
 	}
 	if input.Description != nil {
-		setValues = append(setValues, fmt.Sprintf("description=$%d", argId))
-		args = append(args, *input.Description)
-		argId++
+		fields["description"] = *input.Description
 	}
 	if input.Done != nil {
-		setValues = append(setValues, fmt.Sprintf("done=$%d", argId))
-		args = append(args, *input.Done)
-		argId++
+		fields["done"] = *input.Done
+	}
+
+	belongsToUserQuery := fmt.Sprintf(
+		"id IN (SELECT li.item_id FROM %s li INNER JOIN %s ul ON ul.list_id = li.list_id WHERE ul.user_id = ?)",
+		listsItemsTable, usersListTable,
+	)
+	query, args, err := patch.BuildUpdate(todoItemTable, fields, todoItemUpdatableColumns,
+		sq.Eq{"id": itemId},
+		sq.Expr(belongsToUserQuery, userId),
+	)
+	if err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{"query": query, "args": args}).Debug("executing update query")
+
+	return WithTx(ctx, r.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, query, args...)
+		return err
+	})
+}
+
+func todoItemFromRow(row pgcore.TodoItem) todo.TodoItem {
+	return todo.TodoItem{
+		Id:          int(row.ID),
+		Title:       row.Title,
+		Description: row.Description,
+		Done:        row.Done,
 	}
-	setQuery := strings.Join(setValues, ", ")
-	query := fmt.Sprintf("UPDATE %s ti SET %s FROM %s li, %s ul WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $%d AND ti.id = $%d", todoItemTable, setQuery, listsItemsTable, usersListTable, argId, argId+1)
-	fmt.Println(query)
-	args = append(args, userId, itemId)
-	logrus.Debugf("updateQuery %s", query)
-	logrus.Debugf("args %s", args)
-	_, err := r.db.Exec(query, args...)
-	return err
 }
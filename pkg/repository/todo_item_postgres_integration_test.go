@@ -0,0 +1,155 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	todo "github.com/haliivi/go-todo-app"
+	"github.com/haliivi/go-todo-app/pkg/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestTodoItemPostgres_GetById_RejectsOtherUsersItems(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pool := newTestPool(t)
+	repo := repository.NewTodoItemPostgres(pool)
+
+	ownerId, _, itemId := seedUserWithListAndItem(ctx, t, pool, "owner")
+	otherId, _, _ := seedUserWithListAndItem(ctx, t, pool, "other")
+
+	if _, err := repo.GetById(ctx, ownerId, itemId); err != nil {
+		t.Fatalf("owner should be able to read their own item: %v", err)
+	}
+
+	if _, err := repo.GetById(ctx, otherId, itemId); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected pgx.ErrNoRows for another user's item, got %v", err)
+	}
+}
+
+func TestTodoItemPostgres_CreateGetAllDelete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pool := newTestPool(t)
+	repo := repository.NewTodoItemPostgres(pool)
+
+	userId, listId, _ := seedUserWithListAndItem(ctx, t, pool, "alice")
+
+	itemId, err := repo.Create(ctx, listId, todo.TodoItem{Title: "buy milk", Description: "2%"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	items, err := repo.GetAll(ctx, userId, listId)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items on the list, got %d", len(items))
+	}
+
+	if err := repo.Delete(ctx, userId, itemId); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.GetById(ctx, userId, itemId); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected pgx.ErrNoRows after delete, got %v", err)
+	}
+}
+
+func TestTodoItemPostgres_Update(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pool := newTestPool(t)
+	repo := repository.NewTodoItemPostgres(pool)
+
+	userId, _, itemId := seedUserWithListAndItem(ctx, t, pool, "bob")
+
+	done := true
+	if err := repo.Update(ctx, userId, itemId, todo.UpdateItemInput{Done: &done}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	item, err := repo.GetById(ctx, userId, itemId)
+	if err != nil {
+		t.Fatalf("GetById: %v", err)
+	}
+	if !item.Done {
+		t.Fatalf("expected item to be marked done")
+	}
+
+	if err := repo.Update(ctx, userId, itemId, todo.UpdateItemInput{}); !errors.Is(err, todo.ErrNoUpdateFields) {
+		t.Fatalf("expected todo.ErrNoUpdateFields for an empty patch, got %v", err)
+	}
+}
+
+func TestTodoItemPostgres_CreateBatch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pool := newTestPool(t)
+	repo := repository.NewTodoItemPostgres(pool)
+
+	userId, listId, _ := seedUserWithListAndItem(ctx, t, pool, "carol")
+
+	batch := []todo.TodoItem{
+		{Title: "item 1", Description: "d1"},
+		{Title: "item 2", Description: "d2"},
+		{Title: "item 3", Description: "d3"},
+	}
+	ids, err := repo.CreateBatch(ctx, listId, batch)
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if len(ids) != len(batch) {
+		t.Fatalf("expected %d ids, got %d", len(batch), len(ids))
+	}
+
+	items, err := repo.GetAll(ctx, userId, listId)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(items) != len(batch)+1 { // the item seeded by seedUserWithListAndItem
+		t.Fatalf("expected %d items on the list, got %d", len(batch)+1, len(items))
+	}
+	for _, id := range ids {
+		found := false
+		for _, item := range items {
+			if item.Id == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("batch id %d missing from GetAll result", id)
+		}
+	}
+
+	var itemCountBefore, listItemCountBefore int
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM todo_items").Scan(&itemCountBefore); err != nil {
+		t.Fatalf("count todo_items: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM lists_items").Scan(&listItemCountBefore); err != nil {
+		t.Fatalf("count lists_items: %v", err)
+	}
+
+	// A non-existent list id violates the lists_items foreign key on the
+	// second COPY, which should roll back the todo_items COPY too.
+	const missingListId = -1
+	if _, err := repo.CreateBatch(ctx, missingListId, batch); err == nil {
+		t.Fatalf("expected CreateBatch against a missing list to fail")
+	}
+
+	var itemCountAfter, listItemCountAfter int
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM todo_items").Scan(&itemCountAfter); err != nil {
+		t.Fatalf("count todo_items: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM lists_items").Scan(&listItemCountAfter); err != nil {
+		t.Fatalf("count lists_items: %v", err)
+	}
+	if itemCountAfter != itemCountBefore || listItemCountAfter != listItemCountBefore {
+		t.Fatalf("expected failed batch to roll back, todo_items %d->%d, lists_items %d->%d",
+			itemCountBefore, itemCountAfter, listItemCountBefore, listItemCountAfter)
+	}
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/haliivi/go-todo-app"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+)
+
+func TestTodoItemPostgres_CreateBatch_IssuesCopyForBothTables(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("new mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	items := []todo.TodoItem{
+		{Title: "buy milk", Description: "2%"},
+		{Title: "walk the dog"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT nextval(pg_get_serial_sequence('todo_items', 'id')) FROM generate_series(1, $1)")).
+		WithArgs(len(items)).
+		WillReturnRows(pgxmock.NewRows([]string{"nextval"}).AddRow(10).AddRow(11))
+	mock.ExpectCopyFrom(pgx.Identifier{todoItemTable}, []string{"id", "title", "description"}).
+		WillReturnResult(int64(len(items)))
+	mock.ExpectCopyFrom(pgx.Identifier{listsItemsTable}, []string{"list_id", "item_id"}).
+		WillReturnResult(int64(len(items)))
+	mock.ExpectCommit()
+
+	repo := &TodoItemPostgres{pool: mock}
+
+	ids, err := repo.CreateBatch(context.Background(), 1, items)
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 10 || ids[1] != 11 {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTodoItemPostgres_CreateBatch_RollsBackOnMidBatchFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("new mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	items := []todo.TodoItem{
+		{Title: "buy milk", Description: "2%"},
+		{Title: "walk the dog"},
+	}
+	copyErr := errors.New("duplicate key value violates unique constraint")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT nextval(pg_get_serial_sequence('todo_items', 'id')) FROM generate_series(1, $1)")).
+		WithArgs(len(items)).
+		WillReturnRows(pgxmock.NewRows([]string{"nextval"}).AddRow(10).AddRow(11))
+	mock.ExpectCopyFrom(pgx.Identifier{todoItemTable}, []string{"id", "title", "description"}).
+		WillReturnError(copyErr)
+	mock.ExpectRollback()
+
+	repo := &TodoItemPostgres{pool: mock}
+
+	if _, err := repo.CreateBatch(context.Background(), 1, items); !errors.Is(err, copyErr) {
+		t.Fatalf("expected copy error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations, lists_items COPY or commit ran when it should not have: %v", err)
+	}
+}
+
+func TestTodoItemPostgres_CreateBatch_RejectsOversizedBatch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("new mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	items := make([]todo.TodoItem, MaxCreateBatchItems+1)
+
+	repo := &TodoItemPostgres{pool: mock}
+
+	if _, err := repo.CreateBatch(context.Background(), 1, items); !errors.Is(err, ErrCreateBatchTooLarge) {
+		t.Fatalf("expected ErrCreateBatchTooLarge, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("oversized batch should be rejected before touching the pool: %v", err)
+	}
+}
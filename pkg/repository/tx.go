@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+
+	maxTxRetries     = 3
+	txRetryBaseDelay = 20 * time.Millisecond
+)
+
+// txBeginner is satisfied by *pgxpool.Pool and by pgx mocks used in tests,
+// so WithTx doesn't have to depend on the concrete pool type.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// WithTx runs fn inside a transaction opened with txOptions, committing on
+// success and rolling back on error or panic. A transaction that fails with
+// a serialization failure (40001) or deadlock (40P01) is retried a bounded
+// number of times with exponential backoff before the error is returned.
+func WithTx(ctx context.Context, pool txBeginner, txOptions pgx.TxOptions, fn func(tx pgx.Tx) error) error {
+	var err error
+	for attempt := 0; attempt <= maxTxRetries; attempt++ {
+		if attempt > 0 {
+			delay := txRetryBaseDelay * time.Duration(math.Pow(2, float64(attempt-1)))
+			logrus.WithFields(logrus.Fields{"attempt": attempt, "delay": delay}).Warn("retrying transaction after serialization failure or deadlock")
+			if err := sleep(ctx, delay); err != nil {
+				return err
+			}
+		}
+
+		err = runTx(ctx, pool, txOptions, fn)
+		if !isRetryableTxError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// sleep blocks for delay, returning early with ctx.Err() if ctx is
+// canceled or its deadline expires first.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func runTx(ctx context.Context, pool txBeginner, txOptions pgx.TxOptions, fn func(tx pgx.Tx) error) (err error) {
+	tx, err := pool.BeginTx(ctx, txOptions)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	err = fn(tx)
+	return err
+}
+
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v3"
+)
+
+func TestWithTx_RetryRespectsContextCancellation(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("new mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serializationErr := &pgconn.PgError{Code: "40001"}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	start := time.Now()
+	err = WithTx(ctx, mock, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		// Cancel once the first attempt is underway, so the retry loop's
+		// backoff before attempt 2 should return immediately instead of
+		// sleeping out txRetryBaseDelay.
+		cancel()
+		return serializationErr
+	})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed >= txRetryBaseDelay {
+		t.Fatalf("WithTx took %v, expected it to abort before the %v backoff elapsed", elapsed, txRetryBaseDelay)
+	}
+
+	// Only the first attempt's Begin/Rollback should have happened; a
+	// second attempt would mean the cancellation was ignored.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations, a retry ran after the context was canceled: %v", err)
+	}
+}